@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	conditionInPattern = regexp.MustCompile(`^(\w+)\s+in\s+\[(.*)\]$`)
+	conditionEqPattern = regexp.MustCompile(`^(\w+)\s*(==|!=)\s*(.+)$`)
+)
+
+// parseCondition parses a VisibleWhen expression such as `status==active`,
+// `status!=active` or `role in [admin,owner]`.
+func parseCondition(expr string) (*Condition, error) {
+	expr = strings.TrimSpace(expr)
+
+	if m := conditionInPattern.FindStringSubmatch(expr); m != nil {
+		var values []string
+		for _, v := range strings.Split(m[2], ",") {
+			values = append(values, unquote(strings.TrimSpace(v)))
+		}
+		return &Condition{Field: m[1], Op: "in", Values: values}, nil
+	}
+
+	if m := conditionEqPattern.FindStringSubmatch(expr); m != nil {
+		return &Condition{Field: m[1], Op: m[2], Values: []string{unquote(strings.TrimSpace(m[3]))}}, nil
+	}
+
+	return nil, fmt.Errorf("invalid visibleWhen expression %q", expr)
+}
+
+func unquote(s string) string {
+	return strings.Trim(s, `"'`)
+}
+
+// evaluate reports whether formData satisfies the condition.
+func (c *Condition) evaluate(formData map[string]string) bool {
+	actual := formData[c.Field]
+	switch c.Op {
+	case "==":
+		return actual == c.Values[0]
+	case "!=":
+		return actual != c.Values[0]
+	case "in":
+		for _, v := range c.Values {
+			if actual == v {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// validateSubmission enforces each visible field's Validation and Options
+// against formData, returning a field name -> error message map.
+func validateSubmission(form *Form, formData map[string]string) map[string]string {
+	errs := make(map[string]string)
+
+	for _, field := range form.Fields {
+		if field.VisibleWhen != "" {
+			cond, err := parseCondition(field.VisibleWhen)
+			if err == nil && !cond.evaluate(formData) {
+				continue
+			}
+		}
+
+		value, present := formData[field.Name]
+
+		if field.Validation != nil && field.Validation.Required && strings.TrimSpace(value) == "" {
+			errs[field.Name] = "this field is required"
+			continue
+		}
+		if !present || value == "" {
+			continue
+		}
+
+		if len(field.Options) > 0 && !optionAllowed(field.Options, value) {
+			errs[field.Name] = "value is not one of the allowed options"
+			continue
+		}
+
+		if field.Validation == nil {
+			continue
+		}
+
+		if field.Validation.Pattern != "" {
+			if re, err := regexp.Compile(field.Validation.Pattern); err == nil && !re.MatchString(value) {
+				errs[field.Name] = "value does not match the required pattern"
+				continue
+			}
+		}
+
+		if field.Validation.Min != nil || field.Validation.Max != nil {
+			num, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				errs[field.Name] = "value must be a number"
+				continue
+			}
+			if field.Validation.Min != nil && num < *field.Validation.Min {
+				errs[field.Name] = fmt.Sprintf("value must be at least %v", *field.Validation.Min)
+				continue
+			}
+			if field.Validation.Max != nil && num > *field.Validation.Max {
+				errs[field.Name] = fmt.Sprintf("value must be at most %v", *field.Validation.Max)
+				continue
+			}
+		}
+	}
+
+	return errs
+}
+
+func optionAllowed(options []Option, value string) bool {
+	for _, option := range options {
+		if option.Value == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package main
+
+import "testing"
+
+func TestParseCondition(t *testing.T) {
+	cases := []struct {
+		name    string
+		expr    string
+		wantOp  string
+		wantErr bool
+	}{
+		{name: "equals", expr: "status==active", wantOp: "=="},
+		{name: "not equals", expr: "status != active", wantOp: "!="},
+		{name: "in", expr: `role in [admin, "owner"]`, wantOp: "in"},
+		{name: "invalid", expr: "not a condition", wantErr: true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cond, err := parseCondition(tc.expr)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for %q", tc.expr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseCondition(%q): %v", tc.expr, err)
+			}
+			if cond.Op != tc.wantOp {
+				t.Fatalf("parseCondition(%q).Op = %q, want %q", tc.expr, cond.Op, tc.wantOp)
+			}
+		})
+	}
+}
+
+func TestConditionEvaluate(t *testing.T) {
+	cond, err := parseCondition("role in [admin,owner]")
+	if err != nil {
+		t.Fatalf("parseCondition: %v", err)
+	}
+
+	if !cond.evaluate(map[string]string{"role": "owner"}) {
+		t.Fatal("expected role=owner to satisfy the \"in\" condition")
+	}
+	if cond.evaluate(map[string]string{"role": "guest"}) {
+		t.Fatal("expected role=guest not to satisfy the \"in\" condition")
+	}
+}
+
+func TestValidateSubmissionRequired(t *testing.T) {
+	form := &Form{Fields: []Field{
+		{Name: "email", Type: "email", Validation: &Validation{Required: true}},
+	}}
+
+	if errs := validateSubmission(form, map[string]string{}); errs["email"] == "" {
+		t.Fatal("expected a required-field error for a missing email")
+	}
+	if errs := validateSubmission(form, map[string]string{"email": "a@b.com"}); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateSubmissionSkipsHiddenFields(t *testing.T) {
+	form := &Form{Fields: []Field{
+		{Name: "plan", Type: "text"},
+		{Name: "seats", Type: "number", VisibleWhen: "plan==team", Validation: &Validation{Required: true}},
+	}}
+
+	// seats is required only when plan==team, so leaving it blank for any
+	// other plan must not produce a validation error.
+	errs := validateSubmission(form, map[string]string{"plan": "solo"})
+	if len(errs) != 0 {
+		t.Fatalf("expected no errors for a hidden required field, got %v", errs)
+	}
+}
+
+func TestValidateSubmissionRange(t *testing.T) {
+	min, max := 1.0, 5.0
+	form := &Form{Fields: []Field{
+		{Name: "rating", Type: "number", Validation: &Validation{Min: &min, Max: &max}},
+	}}
+
+	if errs := validateSubmission(form, map[string]string{"rating": "9"}); errs["rating"] == "" {
+		t.Fatal("expected an out-of-range error for rating=9")
+	}
+	if errs := validateSubmission(form, map[string]string{"rating": "3"}); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestValidateSubmissionOptions(t *testing.T) {
+	form := &Form{Fields: []Field{
+		{Name: "color", Type: "select", Options: []Option{{Label: "Red", Value: "red"}, {Label: "Blue", Value: "blue"}}},
+	}}
+
+	if errs := validateSubmission(form, map[string]string{"color": "green"}); errs["color"] == "" {
+		t.Fatal("expected an error for a value outside the configured options")
+	}
+	if errs := validateSubmission(form, map[string]string{"color": "blue"}); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
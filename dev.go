@@ -0,0 +1,162 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"text/template"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+var (
+	formTemplate atomic.Value // holds *template.Template
+
+	reloadUpgrader = websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+	reloadClients   = make(map[*websocket.Conn]bool)
+	reloadClientsMu sync.Mutex
+)
+
+// loadFormTemplate (re)parses form-build/form.html and any sibling *.html
+// partials and atomically swaps the cached template used by makeHTML.
+func loadFormTemplate() error {
+	t, err := template.ParseGlob(filepath.Join("form-build", "*.html"))
+	if err != nil {
+		return fmt.Errorf("failed to parse form templates: %w", err)
+	}
+	formTemplate.Store(t)
+	return nil
+}
+
+// currentFormTemplate returns the cached template, parsing it on first use.
+func currentFormTemplate() (*template.Template, error) {
+	if t, ok := formTemplate.Load().(*template.Template); ok {
+		return t, nil
+	}
+	if err := loadFormTemplate(); err != nil {
+		return nil, err
+	}
+	return formTemplate.Load().(*template.Template), nil
+}
+
+// startTemplateWatcher watches form-build for *.html changes, reloading the
+// cached template and notifying connected dev clients over WebSocket.
+func startTemplateWatcher() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to start template watcher: %w", err)
+	}
+
+	if err := watcher.Add("form-build"); err != nil {
+		return fmt.Errorf("failed to watch form-build: %w", err)
+	}
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if filepath.Ext(event.Name) != ".html" || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if err := loadFormTemplate(); err != nil {
+					log.Printf("dev: failed to reload templates: %v", err)
+					continue
+				}
+				log.Printf("dev: reloaded templates after change to %s", event.Name)
+				broadcastReload()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("dev: watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// broadcastReload pushes a reload event to every connected dev client.
+func broadcastReload() {
+	reloadClientsMu.Lock()
+	defer reloadClientsMu.Unlock()
+
+	for conn := range reloadClients {
+		if err := conn.WriteJSON(map[string]string{"type": "reload"}); err != nil {
+			conn.Close()
+			delete(reloadClients, conn)
+		}
+	}
+}
+
+// devReloadHandler upgrades to a WebSocket connection that receives a
+// {"type":"reload"} message whenever a template file changes.
+func devReloadHandler(c echo.Context) error {
+	conn, err := reloadUpgrader.Upgrade(c.Response(), c.Request(), nil)
+	if err != nil {
+		return err
+	}
+
+	reloadClientsMu.Lock()
+	reloadClients[conn] = true
+	reloadClientsMu.Unlock()
+
+	defer func() {
+		reloadClientsMu.Lock()
+		delete(reloadClients, conn)
+		reloadClientsMu.Unlock()
+		conn.Close()
+	}()
+
+	// Block reading so we notice the client going away; nothing is expected
+	// from it, reload pushes happen from broadcastReload.
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return nil
+		}
+	}
+}
+
+// previewFormHandler renders a form definition taken from the request body
+// or a "form" query parameter through the form template without persisting
+// it, so designers can preview edits before saving.
+func previewFormHandler(c echo.Context) error {
+	form := new(Form)
+
+	if raw := c.QueryParam("form"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), form); err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid form structure"})
+		}
+	} else if err := c.Bind(form); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid form structure"})
+	}
+
+	definition, err := json.Marshal(form)
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+
+	html, err := makeHTML(map[string]interface{}{
+		"data":         string(definition),
+		"url":          "",
+		"formID":       "preview",
+		"clientXToken": "",
+		"dev":          devMode,
+	})
+	if err != nil {
+		return c.JSON(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+	}
+
+	return c.HTML(http.StatusOK, html)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pobyzaarif/form-builder/storage"
+	"github.com/xuri/excelize/v2"
+)
+
+var filenameUnsafe = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// exportFilename derives a safe attachment filename from the form title,
+// falling back to formID when the title sanitizes away to nothing.
+func exportFilename(title, formID, format string) string {
+	base := filenameUnsafe.ReplaceAllString(strings.TrimSpace(title), "_")
+	if strings.Trim(base, "_") == "" {
+		base = formID
+	}
+	return fmt.Sprintf("%s.%s", base, format)
+}
+
+// payloadRecord decodes a submission's flat field-name -> value payload.
+func payloadRecord(payload []byte) (map[string]string, error) {
+	var record map[string]string
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return nil, fmt.Errorf("failed to decode submission: %w", err)
+	}
+	return record, nil
+}
+
+// exportCSV streams submissions as CSV, with column order and headers
+// driven by the form's own field definitions rather than map iteration
+// order of whichever submission happens to be read first.
+func exportCSV(c echo.Context, form *Form, submissions []storage.Submission) error {
+	c.Response().Header().Set(echo.HeaderContentType, "text/csv")
+	c.Response().WriteHeader(http.StatusOK)
+
+	writer := csv.NewWriter(c.Response())
+
+	headers := make([]string, len(form.Fields))
+	for i, field := range form.Fields {
+		headers[i] = field.Label
+	}
+	if err := writer.Write(headers); err != nil {
+		return err
+	}
+
+	for _, sub := range submissions {
+		record, err := payloadRecord(sub.Payload)
+		if err != nil {
+			return err
+		}
+
+		row := make([]string, len(form.Fields))
+		for i, field := range form.Fields {
+			row[i] = record[field.Name]
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+		writer.Flush()
+	}
+
+	return writer.Error()
+}
+
+// exportJSONL streams one JSON object per submission, keyed by field name.
+func exportJSONL(c echo.Context, form *Form, submissions []storage.Submission) error {
+	c.Response().Header().Set(echo.HeaderContentType, "application/x-ndjson")
+	c.Response().WriteHeader(http.StatusOK)
+
+	encoder := json.NewEncoder(c.Response())
+	for _, sub := range submissions {
+		record, err := payloadRecord(sub.Payload)
+		if err != nil {
+			return err
+		}
+
+		row := make(map[string]string, len(form.Fields))
+		for _, field := range form.Fields {
+			if value, ok := record[field.Name]; ok {
+				row[field.Name] = value
+			}
+		}
+		if err := encoder.Encode(row); err != nil {
+			return err
+		}
+		c.Response().Flush()
+	}
+
+	return nil
+}
+
+// exportXLSX streams submissions into a single-sheet workbook using
+// excelize's row-at-a-time StreamWriter.
+func exportXLSX(c echo.Context, form *Form, submissions []storage.Submission) error {
+	f := excelize.NewFile()
+	defer f.Close()
+
+	const sheet = "Submissions"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	streamWriter, err := f.NewStreamWriter(sheet)
+	if err != nil {
+		return err
+	}
+
+	headers := make([]interface{}, len(form.Fields))
+	for i, field := range form.Fields {
+		headers[i] = field.Label
+	}
+	if err := streamWriter.SetRow("A1", headers); err != nil {
+		return err
+	}
+
+	for i, sub := range submissions {
+		record, err := payloadRecord(sub.Payload)
+		if err != nil {
+			return err
+		}
+
+		row := make([]interface{}, len(form.Fields))
+		for j, field := range form.Fields {
+			row[j] = record[field.Name]
+		}
+
+		cell, err := excelize.CoordinatesToCellName(1, i+2)
+		if err != nil {
+			return err
+		}
+		if err := streamWriter.SetRow(cell, row); err != nil {
+			return err
+		}
+	}
+
+	if err := streamWriter.Flush(); err != nil {
+		return err
+	}
+
+	c.Response().Header().Set(echo.HeaderContentType, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Response().WriteHeader(http.StatusOK)
+	return f.Write(c.Response())
+}
@@ -3,17 +3,17 @@ package main
 import (
 	"bytes"
 	"context"
-	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"log"
+	"mime/multipart"
 	"net/http"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"regexp"
 	"sort"
-	"sync"
-	"text/template"
+	"strings"
 	"time"
 
 	"github.com/caarlos0/env/v11"
@@ -22,6 +22,9 @@ import (
 	"github.com/joho/godotenv"
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"github.com/patrickmn/go-cache"
+	"github.com/pobyzaarif/form-builder/storage"
+	"golang.org/x/time/rate"
 )
 
 type Field struct {
@@ -29,11 +32,43 @@ type Field struct {
 	Type        string `json:"type" validate:"required,fieldtype"`
 	Name        string `json:"name" validate:"required,alphanum"`
 	Placeholder string `json:"placeholder"`
+	// Accept restricts uploaded MIME types for "file"/"image" fields, e.g. "image/*".
+	Accept string `json:"accept,omitempty"`
+	// Options lists the choices for "select"/"radio" fields.
+	Options []Option `json:"options,omitempty" validate:"omitempty,dive"`
+	// Default pre-fills the field on render.
+	Default string `json:"default,omitempty"`
+	// VisibleWhen is a simple "field==value", "field!=value" or
+	// "field in [a,b]" expression gating this field's visibility.
+	VisibleWhen string      `json:"visibleWhen,omitempty" validate:"omitempty,condition"`
+	Validation  *Validation `json:"validation,omitempty"`
+}
+
+// Option is one choice of a "select"/"radio" field.
+type Option struct {
+	Label string `json:"label" validate:"required"`
+	Value string `json:"value" validate:"required"`
+}
+
+// Validation describes the constraints enforced against a submitted value.
+type Validation struct {
+	Required bool     `json:"required,omitempty"`
+	Min      *float64 `json:"min,omitempty"`
+	Max      *float64 `json:"max,omitempty"`
+	Pattern  string   `json:"pattern,omitempty" validate:"omitempty,regexpattern"`
+}
+
+// Condition is a parsed VisibleWhen expression.
+type Condition struct {
+	Field  string
+	Op     string // "==", "!=" or "in"
+	Values []string
 }
 
 type Form struct {
-	Title  string  `json:"title" validate:"required"`
-	Fields []Field `json:"fields" validate:"required,dive"`
+	Title    string          `json:"title" validate:"required"`
+	Fields   []Field         `json:"fields" validate:"required,dive"`
+	Updaters []UpdaterConfig `json:"updaters,omitempty" validate:"omitempty,dive"`
 }
 
 var (
@@ -45,9 +80,21 @@ var (
 		"number":   true,
 		"date":     true,
 		"checkbox": true,
+		"file":     true,
+		"image":    true,
+		"select":   true,
+		"radio":    true,
 	}
 
 	allowedClientKeys = make([]string, 0)
+
+	formStore  storage.FormStore
+	nonceCache *cache.Cache
+
+	// devMode is set in main() from the --dev flag and threaded into
+	// rendered templates so form.html only opens the live-reload
+	// WebSocket when the dev endpoints are actually registered.
+	devMode bool
 )
 
 func fieldTypeValidator(fl validator.FieldLevel) bool {
@@ -56,6 +103,41 @@ func fieldTypeValidator(fl validator.FieldLevel) bool {
 	return valid
 }
 
+// regexPatternValidator checks that a Validation.Pattern, if set, compiles.
+func regexPatternValidator(fl validator.FieldLevel) bool {
+	pattern := fl.Field().String()
+	if pattern == "" {
+		return true
+	}
+	_, err := regexp.Compile(pattern)
+	return err == nil
+}
+
+// conditionValidator checks that a Field.VisibleWhen, if set, parses.
+func conditionValidator(fl validator.FieldLevel) bool {
+	expr := fl.Field().String()
+	if expr == "" {
+		return true
+	}
+	_, err := parseCondition(expr)
+	return err == nil
+}
+
+// fieldStructLevelValidation enforces cross-field constraints on a Field
+// that a single-tag validator can't express.
+func fieldStructLevelValidation(sl validator.StructLevel) {
+	field := sl.Current().Interface().(Field)
+
+	if (field.Type == "select" || field.Type == "radio") && len(field.Options) == 0 {
+		sl.ReportError(field.Options, "Options", "Options", "options_required", "")
+	}
+
+	if field.Validation != nil && field.Validation.Min != nil && field.Validation.Max != nil &&
+		*field.Validation.Min > *field.Validation.Max {
+		sl.ReportError(field.Validation.Max, "Max", "Max", "max_lt_min", "")
+	}
+}
+
 func main() {
 	spew.Dump() // i usually use this to debug
 
@@ -64,10 +146,41 @@ func main() {
 		log.Panic(err)
 	}
 
-	// c := cache.New(conf.Cache.DEFAULT_EXPIRATION, conf.Cache.CLEANUP_INTERVAL)
+	nonceCache = cache.New(conf.Cache.DEFAULT_EXPIRATION, conf.Cache.CLEANUP_INTERVAL)
 
 	json.Unmarshal([]byte(conf.ClientKeys), &allowedClientKeys)
 
+	store, err := storage.New(conf.APPConfig.Storage, conf.APPConfig.StorageDSN)
+	if err != nil {
+		log.Panic(err)
+	}
+	formStore = store
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(formStore); err != nil {
+			log.Fatalf("migrate: %v", err)
+		}
+		return
+	}
+
+	if err := loadFormTemplate(); err != nil {
+		log.Panic(err)
+	}
+
+	dev := false
+	for _, arg := range os.Args[1:] {
+		if arg == "--dev" {
+			dev = true
+		}
+	}
+	devMode = dev
+	if dev {
+		if err := startTemplateWatcher(); err != nil {
+			log.Fatalf("dev: %v", err)
+		}
+		log.Print("dev mode enabled: watching form-build for template changes")
+	}
+
 	e := echo.New()
 	e.HideBanner = true
 	e.HidePort = true
@@ -75,11 +188,14 @@ func main() {
 	e.Use(middleware.Logger())
 	e.Use(middleware.Recover())
 
-	registerPath(e, &conf.APPConfig)
+	registerPath(e, &conf.APPConfig, dev)
 
 	// Validator
 	validate := validator.New()
 	validate.RegisterValidation("fieldtype", fieldTypeValidator)
+	validate.RegisterValidation("regexpattern", regexPatternValidator)
+	validate.RegisterValidation("condition", conditionValidator)
+	validate.RegisterStructValidation(fieldStructLevelValidation, Field{})
 	e.Validator = &CustomValidator{validator: validate}
 
 	e.GET("/", func(c echo.Context) error {
@@ -123,9 +239,14 @@ type CacheConfig struct {
 }
 
 type APPConfig struct {
-	Host   string `env:"HOST" envDefault:"0.0.0.0"`
-	Port   string `env:"PORT" envDefault:"8080"`
-	Domain string `env:"DOMAIN" envDefault:"http://0.0.0.0:8080"`
+	Host           string        `env:"HOST" envDefault:"0.0.0.0"`
+	Port           string        `env:"PORT" envDefault:"8080"`
+	Domain         string        `env:"DOMAIN" envDefault:"http://0.0.0.0:8080"`
+	MaxUploadBytes int64         `env:"MAX_UPLOAD_BYTES" envDefault:"10485760"`
+	Storage        string        `env:"STORAGE" envDefault:"fs"`
+	StorageDSN     string        `env:"STORAGE_DSN"`
+	TokenSecret    string        `env:"TOKEN_SECRET,required"`
+	TokenTTL       time.Duration `env:"TOKEN_TTL" envDefault:"10m"`
 }
 
 // NewConfig creates a new Config.
@@ -165,9 +286,11 @@ func clientKeyMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
 	}
 }
 
-// makeHTML parse template and make html
-func makeHTML(templateFileName string, data interface{}) (string, error) {
-	t, err := template.ParseFiles(templateFileName)
+// makeHTML executes the cached form template against data. The template is
+// parsed once and swapped atomically by loadFormTemplate, so a --dev server
+// can hot-reload it without every request paying ParseFiles.
+func makeHTML(data interface{}) (string, error) {
+	t, err := currentFormTemplate()
 	if err != nil {
 		return "", err
 	}
@@ -184,21 +307,19 @@ func fileExists(path string) bool {
 	return !os.IsNotExist(err)
 }
 
-// Load JSON from file
-func loadJSON(path string) (map[string]interface{}, error) {
-	var form map[string]interface{}
-
-	file, err := os.ReadFile(path)
+// loadForm loads and decodes the saved form definition for formID.
+func loadForm(formID string) (*Form, error) {
+	data, err := formStore.LoadForm(formID)
 	if err != nil {
 		return nil, err
 	}
 
-	err = json.Unmarshal(file, &form)
-	if err != nil {
+	var form Form
+	if err := json.Unmarshal(data, &form); err != nil {
 		return nil, err
 	}
 
-	return form, nil
+	return &form, nil
 }
 
 // sortByKey sorts the input map by its keys and returns a new map with the sorted order.
@@ -222,13 +343,20 @@ func sortByKey(in map[string]string) map[string]string {
 }
 
 // Register API path
-func registerPath(e *echo.Echo, appConf *APPConfig) {
+func registerPath(e *echo.Echo, appConf *APPConfig, dev bool) {
 	failedMissingMandatoryParameterMsg := map[string]string{"message": "Missing mandatory parameter"}
 	failedNotfoundMsg := map[string]string{"message": "Failed get form because the form not found or no longer exists"}
 
 	api := e.Group("/api")
 	// api.Use(clientKeyMiddleware)
 
+	submitMiddlewares := []echo.MiddlewareFunc{submitRateLimiter()}
+	if appConf.MaxUploadBytes > 0 {
+		// Cap the request body before it's read, not after c.MultipartForm
+		// has already buffered an oversized upload to disk.
+		submitMiddlewares = append(submitMiddlewares, middleware.BodyLimit(fmt.Sprintf("%dB", appConf.MaxUploadBytes)))
+	}
+
 	// Route to save the form JSON
 	api.POST("/save-form", func(c echo.Context) error {
 		form := new(Form)
@@ -240,29 +368,45 @@ func registerPath(e *echo.Echo, appConf *APPConfig) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
 		}
 
-		// Create the directory with the timestamp
+		// The timestamp is the form's ID
 		timestamp := time.Now().UnixMilli()
-		dir := filepath.Join("form-build", fmt.Sprintf("%d", timestamp))
+		formID := fmt.Sprintf("%d", timestamp)
+
+		definition, err := json.MarshalIndent(form, "", "  ")
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to encode form"})
+		}
+
+		if err := formStore.SaveForm(formID, definition); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to save form"})
+		}
+
+		// The updaters sidecar lives on local disk beside uploads regardless of
+		// the configured storage backend. formStore.AppendSubmission already
+		// persists every submission, so an empty list here means no extra
+		// fan-out rather than a default CSV writer.
+		updaters := form.Updaters
+		if updaters == nil {
+			updaters = []UpdaterConfig{}
+		}
+		dir := filepath.Join("form-build", formID)
 		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
 			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create directory"})
 		}
-
-		// Create the form.json file
-		filePath := filepath.Join(dir, "form.json")
-		file, err := os.Create(filePath)
+		updatersFile, err := os.Create(filepath.Join(dir, "updaters.json"))
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create file"})
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create updaters sidecar"})
 		}
-		defer file.Close()
+		defer updatersFile.Close()
 
-		// Write the JSON to the file
-		encoder := json.NewEncoder(file)
-		encoder.SetIndent("", "  ")
-		if err := encoder.Encode(form); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to write to file"})
+		updatersEncoder := json.NewEncoder(updatersFile)
+		updatersEncoder.SetIndent("", "  ")
+		if err := updatersEncoder.Encode(updaters); err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to write updaters sidecar"})
 		}
+		invalidateUpdaterCache(formID)
 
-		return c.JSON(http.StatusOK, map[string]string{"message": "Form saved successfully", "path": filePath})
+		return c.JSON(http.StatusOK, map[string]string{"message": "Form saved successfully", "path": formID})
 	}, clientKeyMiddleware)
 
 	api.GET("/get-form/:formID", func(c echo.Context) error {
@@ -271,21 +415,25 @@ func registerPath(e *echo.Echo, appConf *APPConfig) {
 			return c.JSON(http.StatusBadRequest, failedMissingMandatoryParameterMsg)
 		}
 
-		filePath := filepath.Join("form-build", ID, "form.json")
-		if !fileExists(filePath) {
+		jsonStr, err := formStore.LoadForm(ID)
+		if err == storage.ErrNotFound {
 			return c.JSON(http.StatusNotFound, failedNotfoundMsg)
 		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
 
-		jsonData, err := loadJSON(filePath)
+		token, err := mintSubmissionToken(ID, appConf.TokenSecret, appConf.TokenTTL)
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
 		}
 
-		jsonStr, _ := json.Marshal(jsonData)
-		form, err := makeHTML(filepath.Join("form-build", "form.html"), map[string]interface{}{
+		form, err := makeHTML(map[string]interface{}{
 			"data":         string(jsonStr),
 			"url":          appConf.Domain + filepath.Join("/api/submit-form/", ID),
-			"clientXToken": "x.y.z",
+			"formID":       ID,
+			"clientXToken": token,
+			"dev":          devMode,
 		})
 		if err != nil {
 			return c.JSON(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
@@ -294,9 +442,64 @@ func registerPath(e *echo.Echo, appConf *APPConfig) {
 		return c.HTML(http.StatusOK, form)
 	})
 
+	api.GET("/export-form/:formID", func(c echo.Context) error {
+		ID := c.Param("formID")
+		if ID == "" {
+			return c.JSON(http.StatusBadRequest, failedMissingMandatoryParameterMsg)
+		}
+
+		form, err := loadForm(ID)
+		if err == storage.ErrNotFound {
+			return c.JSON(http.StatusNotFound, failedNotfoundMsg)
+		}
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+		}
+
+		submissions, err := formStore.ListSubmissions(ID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to load submissions"})
+		}
+
+		format := c.QueryParam("format")
+		if format == "" {
+			format = "csv"
+		}
+
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+exportFilename(form.Title, ID, format))
+
+		switch format {
+		case "csv":
+			return exportCSV(c, form, submissions)
+		case "jsonl":
+			return exportJSONL(c, form, submissions)
+		case "xlsx":
+			return exportXLSX(c, form, submissions)
+		default:
+			return c.JSON(http.StatusBadRequest, map[string]string{"message": "Unsupported export format"})
+		}
+	}, clientKeyMiddleware)
+
 	api.POST("/submit-form/:formID", func(c echo.Context) error {
 		formData := make(map[string]string)
-		if err := c.Bind(&formData); err != nil {
+		uploadedFiles := make(map[string]*multipart.FileHeader)
+
+		if strings.HasPrefix(c.Request().Header.Get(echo.HeaderContentType), echo.MIMEMultipartForm) {
+			multipartForm, err := c.MultipartForm()
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid multipart form data"})
+			}
+			for key, values := range multipartForm.Value {
+				if len(values) > 0 {
+					formData[key] = values[0]
+				}
+			}
+			for key, files := range multipartForm.File {
+				if len(files) > 0 {
+					uploadedFiles[key] = files[0]
+				}
+			}
+		} else if err := c.Bind(&formData); err != nil {
 			return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid form data"})
 		}
 
@@ -304,12 +507,41 @@ func registerPath(e *echo.Echo, appConf *APPConfig) {
 			return c.JSON(http.StatusBadRequest, map[string]string{"message": "Invalid form data"})
 		}
 
-		// TODO check token
+		formID := formData["formID"]
 
-		// Create the directory if it doesn't exist
-		dir := filepath.Join("form-build", formData["formID"])
-		if err := os.MkdirAll(dir, os.ModePerm); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to create directory"})
+		if err := verifySubmissionToken(formData["clientXToken"], formID, appConf.TokenSecret, nonceCache); err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"message": err.Error()})
+		}
+
+		form, err := loadForm(formID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to load form definition"})
+		}
+
+		var savedFiles []string
+		if len(uploadedFiles) > 0 {
+			submissionID := newSubmissionID()
+			for _, field := range form.Fields {
+				if field.Type != "file" && field.Type != "image" {
+					continue
+				}
+				fileHeader, ok := uploadedFiles[field.Name]
+				if !ok {
+					continue
+				}
+				relPath, err := saveUploadedFile(fileHeader, field.Name, formID, submissionID, field.Accept, appConf.MaxUploadBytes)
+				if err != nil {
+					removeUploadedFiles(savedFiles)
+					return c.JSON(http.StatusBadRequest, map[string]string{"message": err.Error()})
+				}
+				savedFiles = append(savedFiles, relPath)
+				formData[field.Name] = relPath
+			}
+		}
+
+		if fieldErrs := validateSubmission(form, formData); len(fieldErrs) > 0 {
+			removeUploadedFiles(savedFiles)
+			return c.JSON(http.StatusBadRequest, fieldErrs)
 		}
 
 		// remove unused fields
@@ -320,45 +552,23 @@ func registerPath(e *echo.Echo, appConf *APPConfig) {
 
 		formData = sortByKey(formData)
 
-		// Append the form data to the CSV file
-		csvFilePath := filepath.Join(dir, "form_answer.csv")
-		file, err := os.OpenFile(csvFilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to open CSV file"})
-		}
-		defer file.Close()
-
-		// Check if file is new by checking its size
-		info, err := file.Stat()
+		updaters, err := loadUpdaters(formID)
 		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to get file info"})
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to load submission updaters"})
 		}
-		isNewFile := info.Size() == 0
-
-		var s sync.Mutex
-		s.Lock()
-		defer s.Unlock()
 
-		writer := csv.NewWriter(file)
-		defer writer.Flush()
-
-		// Write headers if new file
-		if isNewFile {
-			headers := make([]string, 0, len(formData))
-			for key := range formData {
-				headers = append(headers, key)
-			}
-			if err := writer.Write(headers); err != nil {
-				return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to write headers to CSV file"})
-			}
+		if errs := dispatchSubmission(updaters, formID, formData); len(errs) > 0 {
+			log.Printf("submission updater errors for form %s: %v", formID, errs)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to dispatch submission to one or more updaters"})
 		}
 
-		record := []string{}
-		for _, value := range formData {
-			record = append(record, value)
+		payload, err := json.Marshal(formData)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to encode submission"})
 		}
-		if err := writer.Write(record); err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to write to CSV file"})
+		if err := formStore.AppendSubmission(formID, payload, referrer, c.Request().Header.Get("x-client-key")); err != nil {
+			log.Printf("failed to persist submission for form %s: %v", formID, err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"message": "Failed to persist submission"})
 		}
 
 		htmlResponse := `<meta http-equiv="refresh" content="3;url=` + referrer + `" />
@@ -366,5 +576,37 @@ func registerPath(e *echo.Echo, appConf *APPConfig) {
 		`
 
 		return c.HTML(http.StatusOK, htmlResponse)
+	}, submitMiddlewares...)
+
+	// Preview and live-reload are only wired up in --dev mode so a
+	// production deployment never exposes unsaved-form rendering.
+	if dev {
+		api.GET("/preview-form", previewFormHandler)
+		api.GET("/dev/reload", devReloadHandler)
+	}
+}
+
+// submitRateLimiter throttles /api/submit-form per client-key, falling back
+// to the caller's IP when no x-client-key header is set.
+func submitRateLimiter() echo.MiddlewareFunc {
+	return middleware.RateLimiterWithConfig(middleware.RateLimiterConfig{
+		Skipper: middleware.DefaultSkipper,
+		Store: middleware.NewRateLimiterMemoryStoreWithConfig(middleware.RateLimiterMemoryStoreConfig{
+			Rate:      rate.Limit(1),
+			Burst:     5,
+			ExpiresIn: 3 * time.Minute,
+		}),
+		IdentifierExtractor: func(c echo.Context) (string, error) {
+			if clientKey := c.Request().Header.Get("x-client-key"); clientKey != "" {
+				return clientKey, nil
+			}
+			return c.RealIP(), nil
+		},
+		ErrorHandler: func(c echo.Context, err error) error {
+			return c.JSON(http.StatusForbidden, map[string]string{"message": "rate limit identification failed"})
+		},
+		DenyHandler: func(c echo.Context, identifier string, err error) error {
+			return c.JSON(http.StatusTooManyRequests, map[string]string{"message": "Too many submissions, please try again later"})
+		},
 	})
 }
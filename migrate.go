@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/pobyzaarif/form-builder/storage"
+)
+
+// runMigrate walks the legacy form-build/<id>/form.json + form_answer.csv
+// layout and imports every form and submission into target.
+func runMigrate(target storage.FormStore) error {
+	entries, err := os.ReadDir("form-build")
+	if err != nil {
+		return fmt.Errorf("migrate: failed to read form-build: %w", err)
+	}
+
+	source := storage.NewFSStore("form-build")
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		formID := entry.Name()
+
+		definition, err := source.LoadForm(formID)
+		if err == storage.ErrNotFound {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("migrate: failed to load form %s: %w", formID, err)
+		}
+		if err := target.SaveForm(formID, definition); err != nil {
+			return fmt.Errorf("migrate: failed to import form %s: %w", formID, err)
+		}
+
+		submissions, err := source.ListSubmissions(formID)
+		if err != nil {
+			return fmt.Errorf("migrate: failed to read submissions for %s: %w", formID, err)
+		}
+		for _, sub := range submissions {
+			if err := target.AppendSubmission(formID, sub.Payload, sub.Referrer, sub.ClientKey); err != nil {
+				return fmt.Errorf("migrate: failed to import submission for %s: %w", formID, err)
+			}
+		}
+
+		log.Printf("migrate: imported form %s (%d submissions)", formID, len(submissions))
+	}
+
+	return nil
+}
@@ -0,0 +1,182 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// FSStore is the original filesystem layout: form-build/<id>/form.json for
+// the definition and form-build/<id>/form_answer.csv for submissions.
+type FSStore struct {
+	baseDir string
+
+	submissionMu   sync.Mutex
+	submissionLock map[string]*sync.Mutex
+}
+
+// NewFSStore returns an FSStore rooted at baseDir.
+func NewFSStore(baseDir string) *FSStore {
+	return &FSStore{baseDir: baseDir, submissionLock: make(map[string]*sync.Mutex)}
+}
+
+// lockFor returns the per-formID mutex serializing form_answer.csv writes,
+// creating it on first use.
+func (s *FSStore) lockFor(formID string) *sync.Mutex {
+	s.submissionMu.Lock()
+	defer s.submissionMu.Unlock()
+
+	mu, ok := s.submissionLock[formID]
+	if !ok {
+		mu = &sync.Mutex{}
+		s.submissionLock[formID] = mu
+	}
+	return mu
+}
+
+func (s *FSStore) formDir(formID string) string {
+	return filepath.Join(s.baseDir, formID)
+}
+
+func (s *FSStore) SaveForm(formID string, definition []byte) error {
+	dir := s.formDir(formID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("fs store: failed to create directory: %w", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "form.json"), definition, 0644); err != nil {
+		return fmt.Errorf("fs store: failed to write form definition: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FSStore) LoadForm(formID string) ([]byte, error) {
+	data, err := os.ReadFile(filepath.Join(s.formDir(formID), "form.json"))
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fs store: failed to read form definition: %w", err)
+	}
+
+	return data, nil
+}
+
+func (s *FSStore) ListForms() ([]string, error) {
+	entries, err := os.ReadDir(s.baseDir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fs store: failed to list forms: %w", err)
+	}
+
+	var ids []string
+	for _, entry := range entries {
+		if entry.IsDir() {
+			ids = append(ids, entry.Name())
+		}
+	}
+	sort.Strings(ids)
+
+	return ids, nil
+}
+
+// AppendSubmission writes payload (a flat field-name -> value JSON object)
+// as a new row of form_answer.csv, writing a header row on first write.
+// Writes for a given formID are serialized so two concurrent submissions to
+// a brand-new form can't both see an empty file and both emit a header row.
+func (s *FSStore) AppendSubmission(formID string, payload []byte, referrer, clientKey string) error {
+	mu := s.lockFor(formID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	var record map[string]string
+	if err := json.Unmarshal(payload, &record); err != nil {
+		return fmt.Errorf("fs store: submission payload must be a flat string map: %w", err)
+	}
+
+	dir := s.formDir(formID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return fmt.Errorf("fs store: failed to create directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(dir, "form_answer.csv"), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("fs store: failed to open CSV file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("fs store: failed to stat CSV file: %w", err)
+	}
+
+	keys := make([]string, 0, len(record))
+	for key := range record {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	writer := csv.NewWriter(file)
+	defer writer.Flush()
+
+	if info.Size() == 0 {
+		if err := writer.Write(keys); err != nil {
+			return fmt.Errorf("fs store: failed to write headers: %w", err)
+		}
+	}
+
+	row := make([]string, 0, len(keys))
+	for _, key := range keys {
+		row = append(row, record[key])
+	}
+	if err := writer.Write(row); err != nil {
+		return fmt.Errorf("fs store: failed to write row: %w", err)
+	}
+
+	return nil
+}
+
+func (s *FSStore) ListSubmissions(formID string) ([]Submission, error) {
+	file, err := os.Open(filepath.Join(s.formDir(formID), "form_answer.csv"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fs store: failed to open CSV file: %w", err)
+	}
+	defer file.Close()
+
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("fs store: failed to read CSV file: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	headers := rows[0]
+	submissions := make([]Submission, 0, len(rows)-1)
+	for _, row := range rows[1:] {
+		record := make(map[string]string, len(headers))
+		for i, header := range headers {
+			if i < len(row) {
+				record[header] = row[i]
+			}
+		}
+
+		payload, err := json.Marshal(record)
+		if err != nil {
+			return nil, fmt.Errorf("fs store: failed to marshal row: %w", err)
+		}
+		submissions = append(submissions, Submission{FormID: formID, Payload: payload})
+	}
+
+	return submissions, nil
+}
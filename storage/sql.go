@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// SQLStore persists forms and submissions in Postgres or SQLite via
+// database/sql, so concurrent writers and ad-hoc queries work out of the
+// box instead of relying on a per-request file lock.
+type SQLStore struct {
+	db     *sql.DB
+	driver string
+}
+
+// NewSQLStore opens dsn with driver ("postgres" or "sqlite3") and ensures
+// the forms/submissions schema exists.
+func NewSQLStore(driver, dsn string) (*SQLStore, error) {
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, fmt.Errorf("sql store: failed to open %s database: %w", driver, err)
+	}
+
+	store := &SQLStore{db: db, driver: driver}
+	if err := store.ensureSchema(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *SQLStore) ensureSchema() error {
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS forms (
+			id TEXT PRIMARY KEY,
+			title TEXT NOT NULL,
+			definition_json TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL
+		)`); err != nil {
+		return fmt.Errorf("sql store: failed to create forms table: %w", err)
+	}
+
+	if _, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS submissions (
+			id TEXT PRIMARY KEY,
+			form_id TEXT NOT NULL,
+			payload_json TEXT NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			referrer TEXT,
+			client_key TEXT
+		)`); err != nil {
+		return fmt.Errorf("sql store: failed to create submissions table: %w", err)
+	}
+
+	return nil
+}
+
+// ph returns the driver-appropriate positional placeholder ($1.. for
+// Postgres, ? for SQLite).
+func (s *SQLStore) ph(n int) string {
+	if s.driver == "postgres" {
+		return fmt.Sprintf("$%d", n)
+	}
+	return "?"
+}
+
+func (s *SQLStore) SaveForm(formID string, definition []byte) error {
+	var parsed struct {
+		Title string `json:"title"`
+	}
+	_ = json.Unmarshal(definition, &parsed)
+
+	query := fmt.Sprintf(`
+		INSERT INTO forms (id, title, definition_json, created_at) VALUES (%s, %s, %s, %s)
+		ON CONFLICT (id) DO UPDATE SET title = EXCLUDED.title, definition_json = EXCLUDED.definition_json`,
+		s.ph(1), s.ph(2), s.ph(3), s.ph(4))
+
+	if _, err := s.db.Exec(query, formID, parsed.Title, string(definition), time.Now()); err != nil {
+		return fmt.Errorf("sql store: failed to save form: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) LoadForm(formID string) ([]byte, error) {
+	query := fmt.Sprintf(`SELECT definition_json FROM forms WHERE id = %s`, s.ph(1))
+
+	var definition string
+	if err := s.db.QueryRow(query, formID).Scan(&definition); err == sql.ErrNoRows {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, fmt.Errorf("sql store: failed to load form: %w", err)
+	}
+
+	return []byte(definition), nil
+}
+
+func (s *SQLStore) ListForms() ([]string, error) {
+	rows, err := s.db.Query(`SELECT id FROM forms ORDER BY created_at`)
+	if err != nil {
+		return nil, fmt.Errorf("sql store: failed to list forms: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("sql store: failed to scan form id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (s *SQLStore) AppendSubmission(formID string, payload []byte, referrer, clientKey string) error {
+	query := fmt.Sprintf(`
+		INSERT INTO submissions (id, form_id, payload_json, created_at, referrer, client_key)
+		VALUES (%s, %s, %s, %s, %s, %s)`,
+		s.ph(1), s.ph(2), s.ph(3), s.ph(4), s.ph(5), s.ph(6))
+
+	_, err := s.db.Exec(query, newSubmissionID(), formID, string(payload), time.Now(), referrer, clientKey)
+	if err != nil {
+		return fmt.Errorf("sql store: failed to append submission: %w", err)
+	}
+
+	return nil
+}
+
+func (s *SQLStore) ListSubmissions(formID string) ([]Submission, error) {
+	query := fmt.Sprintf(`
+		SELECT id, form_id, payload_json, created_at, referrer, client_key
+		FROM submissions WHERE form_id = %s ORDER BY created_at`, s.ph(1))
+
+	rows, err := s.db.Query(query, formID)
+	if err != nil {
+		return nil, fmt.Errorf("sql store: failed to list submissions: %w", err)
+	}
+	defer rows.Close()
+
+	var submissions []Submission
+	for rows.Next() {
+		var sub Submission
+		var payload string
+		if err := rows.Scan(&sub.ID, &sub.FormID, &payload, &sub.CreatedAt, &sub.Referrer, &sub.ClientKey); err != nil {
+			return nil, fmt.Errorf("sql store: failed to scan submission: %w", err)
+		}
+		sub.Payload = []byte(payload)
+		submissions = append(submissions, sub)
+	}
+
+	return submissions, rows.Err()
+}
+
+// newSubmissionID generates a random primary key for a submissions row.
+func newSubmissionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
@@ -0,0 +1,46 @@
+// Package storage persists form definitions and their submissions behind a
+// single interface, independent of the backing medium.
+package storage
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrNotFound is returned when a form cannot be located by its ID.
+var ErrNotFound = errors.New("storage: not found")
+
+// Submission is a single recorded form response.
+type Submission struct {
+	ID        string
+	FormID    string
+	Payload   []byte
+	CreatedAt time.Time
+	Referrer  string
+	ClientKey string
+}
+
+// FormStore persists form definitions and their submissions. SaveForm is
+// also used to update an existing form's definition.
+type FormStore interface {
+	SaveForm(formID string, definition []byte) error
+	LoadForm(formID string) ([]byte, error)
+	ListForms() ([]string, error)
+	AppendSubmission(formID string, payload []byte, referrer, clientKey string) error
+	ListSubmissions(formID string) ([]Submission, error)
+}
+
+// New builds the FormStore selected by kind ("fs", "postgres" or "sqlite").
+// dsn is ignored for "fs".
+func New(kind, dsn string) (FormStore, error) {
+	switch kind {
+	case "", "fs":
+		return NewFSStore("form-build"), nil
+	case "postgres":
+		return NewSQLStore("postgres", dsn)
+	case "sqlite":
+		return NewSQLStore("sqlite3", dsn)
+	default:
+		return nil, errors.New("storage: unknown backend " + kind)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+// submissionTokenPayload is the signed payload minted when /api/get-form
+// renders a page and verified on /api/submit-form, so only formIDs that
+// were actually rendered to a client can be submitted against.
+type submissionTokenPayload struct {
+	FormID string `json:"formID"`
+	Exp    int64  `json:"exp"`
+	Nonce  string `json:"nonce"`
+}
+
+// mintSubmissionToken signs a TTL-bounded token for formID, formatted as
+// "<base64 payload>.<hex hmac>".
+func mintSubmissionToken(formID, secret string, ttl time.Duration) (string, error) {
+	payload := submissionTokenPayload{
+		FormID: formID,
+		Exp:    time.Now().Add(ttl).Unix(),
+		Nonce:  newSubmissionID(),
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal submission token: %w", err)
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(body)
+	return encoded + "." + signToken(encoded, secret), nil
+}
+
+// verifySubmissionToken checks the token's signature, expiry and formID
+// match, and rejects a nonce that has already been redeemed.
+func verifySubmissionToken(token, formID, secret string, nonces *cache.Cache) error {
+	encoded, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return fmt.Errorf("malformed submission token")
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(signToken(encoded, secret))) {
+		return fmt.Errorf("invalid submission token signature")
+	}
+
+	body, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return fmt.Errorf("malformed submission token")
+	}
+
+	var payload submissionTokenPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return fmt.Errorf("malformed submission token")
+	}
+
+	if payload.FormID != formID {
+		return fmt.Errorf("submission token was not issued for this form")
+	}
+	if time.Now().Unix() > payload.Exp {
+		return fmt.Errorf("submission token has expired")
+	}
+	// Add is an atomic check-and-set: using a separate Get then Set would
+	// leave a window where two concurrent requests replaying the same
+	// token both see it as unused before either records it.
+	if err := nonces.Add(payload.Nonce, true, cache.DefaultExpiration); err != nil {
+		return fmt.Errorf("submission token has already been used")
+	}
+
+	return nil
+}
+
+func signToken(encoded, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(encoded))
+	return hex.EncodeToString(mac.Sum(nil))
+}
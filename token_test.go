@@ -0,0 +1,103 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/patrickmn/go-cache"
+)
+
+func newNonceCache() *cache.Cache {
+	return cache.New(time.Minute, time.Minute)
+}
+
+func TestVerifySubmissionTokenRoundTrip(t *testing.T) {
+	token, err := mintSubmissionToken("form-1", "secret", time.Minute)
+	if err != nil {
+		t.Fatalf("mintSubmissionToken: %v", err)
+	}
+
+	if err := verifySubmissionToken(token, "form-1", "secret", newNonceCache()); err != nil {
+		t.Fatalf("verifySubmissionToken: %v", err)
+	}
+}
+
+func TestVerifySubmissionTokenRejectsWrongFormID(t *testing.T) {
+	token, err := mintSubmissionToken("form-1", "secret", time.Minute)
+	if err != nil {
+		t.Fatalf("mintSubmissionToken: %v", err)
+	}
+
+	if err := verifySubmissionToken(token, "form-2", "secret", newNonceCache()); err == nil {
+		t.Fatal("expected an error for a token issued to a different formID")
+	}
+}
+
+func TestVerifySubmissionTokenRejectsBadSignature(t *testing.T) {
+	token, err := mintSubmissionToken("form-1", "secret", time.Minute)
+	if err != nil {
+		t.Fatalf("mintSubmissionToken: %v", err)
+	}
+
+	if err := verifySubmissionToken(token, "form-1", "wrong-secret", newNonceCache()); err == nil {
+		t.Fatal("expected an error for a token signed with a different secret")
+	}
+}
+
+func TestVerifySubmissionTokenRejectsExpired(t *testing.T) {
+	token, err := mintSubmissionToken("form-1", "secret", -time.Minute)
+	if err != nil {
+		t.Fatalf("mintSubmissionToken: %v", err)
+	}
+
+	if err := verifySubmissionToken(token, "form-1", "secret", newNonceCache()); err == nil {
+		t.Fatal("expected an error for an expired token")
+	}
+}
+
+func TestVerifySubmissionTokenRejectsReplay(t *testing.T) {
+	token, err := mintSubmissionToken("form-1", "secret", time.Minute)
+	if err != nil {
+		t.Fatalf("mintSubmissionToken: %v", err)
+	}
+
+	nonces := newNonceCache()
+	if err := verifySubmissionToken(token, "form-1", "secret", nonces); err != nil {
+		t.Fatalf("first verifySubmissionToken: %v", err)
+	}
+	if err := verifySubmissionToken(token, "form-1", "secret", nonces); err == nil {
+		t.Fatal("expected the second use of the same token to be rejected")
+	}
+}
+
+// TestVerifySubmissionTokenRejectsConcurrentReplay guards the nonce check
+// against the Get-then-Set TOCTOU that let two concurrent requests for the
+// same token both pass the "already used" check.
+func TestVerifySubmissionTokenRejectsConcurrentReplay(t *testing.T) {
+	token, err := mintSubmissionToken("form-1", "secret", time.Minute)
+	if err != nil {
+		t.Fatalf("mintSubmissionToken: %v", err)
+	}
+
+	nonces := newNonceCache()
+
+	const attempts = 50
+	var successes int64
+	var wg sync.WaitGroup
+	wg.Add(attempts)
+	for i := 0; i < attempts; i++ {
+		go func() {
+			defer wg.Done()
+			if err := verifySubmissionToken(token, "form-1", "secret", nonces); err == nil {
+				atomic.AddInt64(&successes, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if successes != 1 {
+		t.Fatalf("expected exactly 1 concurrent verification to succeed, got %d", successes)
+	}
+}
@@ -0,0 +1,240 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// SubmissionUpdater dispatches a submitted form record to some downstream
+// system (stdout, webhook, exec, ...). Implementations are resolved per
+// formID from its updaters.json sidecar. formStore.AppendSubmission is the
+// system of record for submissions, so there is deliberately no "write to a
+// file" updater here - that would just duplicate it.
+type SubmissionUpdater interface {
+	UpdateSubmission(formID string, record map[string]string) error
+}
+
+// UpdaterConfig is one entry of a form's form-build/<id>/updaters.json
+// sidecar, describing a single SubmissionUpdater to build.
+type UpdaterConfig struct {
+	Type    string   `json:"type" validate:"required,oneof=stdout webhook exec"`
+	URL     string   `json:"url,omitempty"`
+	Secret  string   `json:"secret,omitempty"`
+	Retries int      `json:"retries,omitempty"`
+	Command string   `json:"command,omitempty"`
+	Args    []string `json:"args,omitempty"`
+}
+
+var (
+	updaterCache   = make(map[string][]SubmissionUpdater)
+	updaterCacheMu sync.Mutex
+)
+
+// loadUpdaters resolves and caches the SubmissionUpdater chain configured
+// for formID, defaulting to no updaters when no updaters.json sidecar
+// exists: formStore.AppendSubmission already persists every submission, so
+// updaters are opt-in fan-out to additional systems, not primary storage.
+func loadUpdaters(formID string) ([]SubmissionUpdater, error) {
+	updaterCacheMu.Lock()
+	defer updaterCacheMu.Unlock()
+
+	if updaters, ok := updaterCache[formID]; ok {
+		return updaters, nil
+	}
+
+	var configs []UpdaterConfig
+	path := filepath.Join("form-build", formID, "updaters.json")
+	if fileExists(path) {
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(file, &configs); err != nil {
+			return nil, err
+		}
+	}
+
+	updaters := make([]SubmissionUpdater, 0, len(configs))
+	for _, cfg := range configs {
+		updater, err := buildUpdater(formID, cfg)
+		if err != nil {
+			return nil, err
+		}
+		updaters = append(updaters, updater)
+	}
+
+	updaterCache[formID] = updaters
+	return updaters, nil
+}
+
+// invalidateUpdaterCache drops the cached updater chain for formID so the
+// next submission re-reads its updaters.json sidecar.
+func invalidateUpdaterCache(formID string) {
+	updaterCacheMu.Lock()
+	defer updaterCacheMu.Unlock()
+	delete(updaterCache, formID)
+}
+
+// buildUpdater constructs the SubmissionUpdater described by cfg.
+func buildUpdater(formID string, cfg UpdaterConfig) (SubmissionUpdater, error) {
+	switch cfg.Type {
+	case "stdout":
+		return &StdoutUpdater{}, nil
+	case "webhook":
+		if cfg.URL == "" {
+			return nil, fmt.Errorf("webhook updater requires a url")
+		}
+		retries := cfg.Retries
+		if retries <= 0 {
+			retries = 3
+		}
+		return &WebhookUpdater{
+			URL:     cfg.URL,
+			Secret:  cfg.Secret,
+			Retries: retries,
+			Client:  &http.Client{Timeout: 10 * time.Second},
+		}, nil
+	case "exec":
+		if cfg.Command == "" {
+			return nil, fmt.Errorf("exec updater requires a command")
+		}
+		return &ExecUpdater{Command: cfg.Command, Args: cfg.Args}, nil
+	default:
+		return nil, fmt.Errorf("unknown updater type %q", cfg.Type)
+	}
+}
+
+// dispatchSubmission fans a submitted record out to every configured
+// updater, aggregating any errors instead of failing fast.
+func dispatchSubmission(updaters []SubmissionUpdater, formID string, record map[string]string) []error {
+	var errs []error
+	for _, updater := range updaters {
+		if err := updater.UpdateSubmission(formID, record); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// StdoutUpdater writes each submission as a single JSON line to stdout.
+type StdoutUpdater struct {
+	mu sync.Mutex
+}
+
+func (u *StdoutUpdater) UpdateSubmission(formID string, record map[string]string) error {
+	line, err := json.Marshal(map[string]interface{}{"formID": formID, "data": record})
+	if err != nil {
+		return fmt.Errorf("stdout updater: failed to marshal record: %w", err)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	_, err = fmt.Fprintln(os.Stdout, string(line))
+	return err
+}
+
+// WebhookUpdater POSTs each submission as JSON to a configured URL, signing
+// the body with HMAC-SHA256 and retrying a fixed number of times on failure.
+type WebhookUpdater struct {
+	URL     string
+	Secret  string
+	Retries int
+	Client  *http.Client
+}
+
+func (u *WebhookUpdater) UpdateSubmission(formID string, record map[string]string) error {
+	body, err := json.Marshal(map[string]interface{}{"formID": formID, "data": record})
+	if err != nil {
+		return fmt.Errorf("webhook updater: failed to marshal record: %w", err)
+	}
+
+	signature := u.sign(body)
+
+	var lastErr error
+	for attempt := 0; attempt <= u.Retries; attempt++ {
+		req, err := http.NewRequest(http.MethodPost, u.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("webhook updater: failed to build request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if signature != "" {
+			req.Header.Set("X-Signature", signature)
+		}
+
+		resp, err := u.Client.Do(req)
+		if err != nil {
+			lastErr = fmt.Errorf("webhook updater: request failed: %w", err)
+			time.Sleep(backoff(attempt))
+			continue
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook updater: unexpected status %d", resp.StatusCode)
+		time.Sleep(backoff(attempt))
+	}
+
+	return lastErr
+}
+
+func (u *WebhookUpdater) sign(body []byte) string {
+	if u.Secret == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(u.Secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func backoff(attempt int) time.Duration {
+	return time.Duration(attempt+1) * 200 * time.Millisecond
+}
+
+// execUpdaterTimeout bounds how long a configured command may run, the same
+// way WebhookUpdater bounds its HTTP client, so a hung command can't block
+// submission dispatch (and the request handling it) indefinitely.
+const execUpdaterTimeout = 10 * time.Second
+
+// ExecUpdater pipes each submission as JSON on stdin to an external binary
+// and treats a non-zero exit code as a failure.
+type ExecUpdater struct {
+	Command string
+	Args    []string
+}
+
+func (u *ExecUpdater) UpdateSubmission(formID string, record map[string]string) error {
+	body, err := json.Marshal(map[string]interface{}{"formID": formID, "data": record})
+	if err != nil {
+		return fmt.Errorf("exec updater: failed to marshal record: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), execUpdaterTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, u.Command, u.Args...)
+	cmd.Stdin = bytes.NewReader(body)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("exec updater: %s timed out after %s", u.Command, execUpdaterTimeout)
+		}
+		return fmt.Errorf("exec updater: %s exited with error: %w (stderr: %s)", u.Command, err, stderr.String())
+	}
+
+	return nil
+}
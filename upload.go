@@ -0,0 +1,115 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var filenameSanitizer = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// saveUploadedFile validates and stores an uploaded "file"/"image" field,
+// returning its path relative to the working directory for recording in
+// the submission record. The destination is prefixed with fieldName so two
+// fields that happen to share an original filename don't overwrite each
+// other.
+func saveUploadedFile(fileHeader *multipart.FileHeader, fieldName, formID, submissionID, accept string, maxBytes int64) (string, error) {
+	if maxBytes > 0 && fileHeader.Size > maxBytes {
+		return "", fmt.Errorf("file %q exceeds the maximum upload size", fileHeader.Filename)
+	}
+
+	src, err := fileHeader.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to open uploaded file: %w", err)
+	}
+	defer src.Close()
+
+	sniff := make([]byte, 512)
+	n, err := io.ReadFull(src, sniff)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("failed to read uploaded file: %w", err)
+	}
+	mimeType := http.DetectContentType(sniff[:n])
+
+	if accept != "" && !mimeMatches(accept, mimeType) {
+		return "", fmt.Errorf("file %q has type %q which is not accepted", fileHeader.Filename, mimeType)
+	}
+
+	if _, err := src.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to rewind uploaded file: %w", err)
+	}
+
+	dir := filepath.Join("form-build", formID, "uploads", submissionID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	destPath := filepath.Join(dir, sanitizeFilename(fieldName)+"_"+sanitizeFilename(fileHeader.Filename))
+	dest, err := os.Create(destPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to create destination file: %w", err)
+	}
+	defer dest.Close()
+
+	if _, err := io.Copy(dest, src); err != nil {
+		return "", fmt.Errorf("failed to write uploaded file: %w", err)
+	}
+
+	return destPath, nil
+}
+
+// mimeMatches reports whether mimeType satisfies an accept pattern such as
+// "image/*", "image/png,application/pdf" or an exact type.
+func mimeMatches(accept, mimeType string) bool {
+	for _, pattern := range strings.Split(accept, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if strings.HasSuffix(pattern, "/*") {
+			if strings.HasPrefix(mimeType, strings.TrimSuffix(pattern, "*")) {
+				return true
+			}
+		} else if pattern == mimeType {
+			return true
+		}
+	}
+	return false
+}
+
+// removeUploadedFiles best-effort deletes files saved by saveUploadedFile,
+// used to clean up after a submission that saved uploads but then failed
+// validation on an unrelated field.
+func removeUploadedFiles(paths []string) {
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("failed to remove orphaned upload %q: %v", path, err)
+		}
+	}
+}
+
+// sanitizeFilename strips directory components and any character outside a
+// safe allow-list so an uploaded filename can't escape its upload directory.
+func sanitizeFilename(name string) string {
+	base := filenameSanitizer.ReplaceAllString(filepath.Base(name), "_")
+	if base == "" || base == "." || base == ".." {
+		base = "file"
+	}
+	return base
+}
+
+// newSubmissionID generates a random identifier used as the upload
+// directory for a single submission.
+func newSubmissionID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}